@@ -0,0 +1,87 @@
+// Package platform selects how to clear the terminal and invoke shell
+// commands for the current operating system. It dispatches on
+// runtime.GOOS through a registry instead of the "OS" environment
+// variable, which can be unset, overridden, or simply absent on
+// non-Windows systems.
+package platform
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+)
+
+// ansiClear is written directly to a writer when no external clear
+// command is available or configured.
+const ansiClear = "\x1b[2J\x1b[H"
+
+// Shell describes how to invoke a command string as a subprocess on a
+// given OS: the binary name and the flags that precede the command
+// string itself.
+type Shell struct {
+	Name string
+	Args []string
+}
+
+// PowerShell is an opt-in Windows shell for users who want
+// "powershell -Command" instead of the default "cmd /c".
+var PowerShell = Shell{Name: "powershell", Args: []string{"-Command"}}
+
+// Platform bundles the OS-specific behavior watchpls needs: how to
+// invoke commands, and how to clear the terminal.
+type Platform struct {
+	Shell Shell
+	// Clear runs an external command to clear the terminal. If it is
+	// nil, or it returns an error, ClearScreen falls back to writing
+	// the ANSI clear sequence directly.
+	Clear func() error
+}
+
+// registry maps runtime.GOOS values to their Platform. Tests can call
+// Register to inject a fake platform under a made-up GOOS key.
+var registry = map[string]Platform{
+	"windows": {
+		Shell: Shell{Name: "cmd", Args: []string{"/c"}},
+		Clear: func() error { return runClear("cmd", "/c", "cls") },
+	},
+}
+
+// defaultPlatform is used for any GOOS not present in registry, which
+// covers Linux, macOS, BSD, and the rest of the Unix-like world.
+var defaultPlatform = Platform{
+	Shell: Shell{Name: "sh", Args: []string{"-c"}},
+	Clear: func() error { return runClear("clear") },
+}
+
+// Register adds or replaces the Platform for goos. It exists so tests
+// can inject a fake platform without depending on the real OS.
+func Register(goos string, p Platform) {
+	registry[goos] = p
+}
+
+// For returns the Platform registered for goos, falling back to
+// defaultPlatform if goos isn't registered.
+func For(goos string) Platform {
+	if p, ok := registry[goos]; ok {
+		return p
+	}
+	return defaultPlatform
+}
+
+// ClearScreen clears the terminal using p's Clear command, falling back
+// to writing the ANSI clear sequence to w if Clear is unset or fails.
+func (p Platform) ClearScreen(w io.Writer) {
+	if p.Clear != nil && p.Clear() == nil {
+		return
+	}
+	fmt.Fprint(w, ansiClear)
+}
+
+// runClear runs name with args, connecting its stdout to os.Stdout so
+// terminal-clearing commands that expect a real tty still work.
+func runClear(name string, args ...string) error {
+	cmd := exec.Command(name, args...)
+	cmd.Stdout = os.Stdout
+	return cmd.Run()
+}
@@ -0,0 +1,48 @@
+package platform
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+func TestForFallsBackToDefaultForUnknownGOOS(t *testing.T) {
+	p := For("plan9")
+	if p.Shell.Name != defaultPlatform.Shell.Name {
+		t.Errorf("got shell %q, want default shell %q", p.Shell.Name, defaultPlatform.Shell.Name)
+	}
+}
+
+func TestRegisterInjectsFakePlatform(t *testing.T) {
+	fake := Platform{Shell: Shell{Name: "fakesh", Args: []string{"-x"}}}
+	Register("faketest", fake)
+
+	p := For("faketest")
+	if p.Shell.Name != "fakesh" {
+		t.Errorf("got shell %q, want %q", p.Shell.Name, "fakesh")
+	}
+}
+
+func TestClearScreenFallsBackToANSI(t *testing.T) {
+	p := Platform{
+		Clear: func() error { return errors.New("no clear binary") },
+	}
+	var buf bytes.Buffer
+	p.ClearScreen(&buf)
+
+	if buf.String() != ansiClear {
+		t.Errorf("got %q, want ANSI clear sequence %q", buf.String(), ansiClear)
+	}
+}
+
+func TestClearScreenSkipsANSIWhenClearSucceeds(t *testing.T) {
+	p := Platform{
+		Clear: func() error { return nil },
+	}
+	var buf bytes.Buffer
+	p.ClearScreen(&buf)
+
+	if buf.Len() != 0 {
+		t.Errorf("expected no fallback output when Clear succeeds, got %q", buf.String())
+	}
+}
@@ -0,0 +1,231 @@
+// Package render implements a diff-based terminal renderer that repaints
+// only the lines that changed between frames, instead of clearing and
+// redrawing the whole screen on every tick. It can optionally highlight
+// the characters that changed, like `watch -d`.
+package render
+
+import (
+	"fmt"
+	"io"
+	"strings"
+)
+
+// clearLine erases from the cursor to the end of the current line.
+const clearLine = "\x1b[K"
+
+// reverseOn and reverseOff wrap a highlighted run of characters in
+// reverse video.
+const (
+	reverseOn  = "\x1b[7m"
+	reverseOff = "\x1b[27m"
+)
+
+// HighlightMode selects how changed characters are highlighted across
+// frames.
+type HighlightMode int
+
+const (
+	// HighlightNone draws frames with no character highlighting.
+	HighlightNone HighlightMode = iota
+	// HighlightOn highlights characters that changed since the
+	// immediately preceding frame.
+	HighlightOn
+	// HighlightPermanent highlights characters that changed since the
+	// immediately preceding frame, and keeps them highlighted in every
+	// later frame even once they stop changing, like `watch -d=cumulative`.
+	HighlightPermanent
+)
+
+// Renderer tracks the previously drawn frame so Draw can repaint only the
+// lines that differ on the next call.
+type Renderer struct {
+	prev []string
+	mode HighlightMode
+	// headerLines leading lines are always drawn plainly, never
+	// diff-highlighted - e.g. a title line carrying a live clock, which
+	// would otherwise highlight (or, in HighlightPermanent, accumulate
+	// sticky highlighting on) every tick regardless of the command's
+	// own output.
+	headerLines int
+	// sticky[i][j] records whether rune j of line i has ever changed,
+	// and is only used in HighlightPermanent mode.
+	sticky [][]bool
+	// lastHighlighted[i] records whether line i carried any highlight
+	// in the most recently drawn frame, so a HighlightOn line whose
+	// text stops changing still gets one more plain repaint to clear a
+	// highlight left over from the previous frame.
+	lastHighlighted []bool
+}
+
+// New returns a Renderer with no prior frame, so the first Draw repaints
+// every line. mode controls whether and how changed characters are
+// highlighted; headerLines leading lines are always drawn plainly.
+func New(mode HighlightMode, headerLines int) *Renderer {
+	return &Renderer{mode: mode, headerLines: headerLines}
+}
+
+// Draw writes lines to w, moving the cursor to and rewriting only the
+// lines that differ from the previous frame (or, in a highlight mode,
+// that carry a highlight or just lost one; in HighlightPermanent a line
+// with sticky highlighting is rewritten on every Draw). Lines left over
+// from a longer previous frame are cleared.
+func (r *Renderer) Draw(w io.Writer, lines []string) {
+	for i, line := range lines {
+		var prevLine string
+		if i < len(r.prev) {
+			prevLine = r.prev[i]
+		}
+		unchanged := i < len(r.prev) && r.prev[i] == line
+
+		if r.mode == HighlightNone || i < r.headerLines {
+			if unchanged {
+				continue
+			}
+			fmt.Fprintf(w, "\x1b[%d;1H%s%s\n", i+1, clearLine, line)
+			continue
+		}
+
+		rendered, highlighted := r.highlightLine(i, prevLine, line)
+
+		wasHighlighted := i < len(r.lastHighlighted) && r.lastHighlighted[i]
+		for len(r.lastHighlighted) <= i {
+			r.lastHighlighted = append(r.lastHighlighted, false)
+		}
+		r.lastHighlighted[i] = highlighted
+
+		// In HighlightOn mode a line highlighted last frame but not
+		// this one still needs a plain repaint even if its text didn't
+		// change, or the reverse video from the previous frame would
+		// stay on screen forever. HighlightPermanent has no such case:
+		// once highlighted, highlightLine keeps reporting it as such.
+		staleHighlight := r.mode == HighlightOn && wasHighlighted && !highlighted
+		if unchanged && !highlighted && !staleHighlight {
+			continue
+		}
+		fmt.Fprintf(w, "\x1b[%d;1H%s%s\n", i+1, clearLine, rendered)
+	}
+	for i := len(lines); i < len(r.prev); i++ {
+		fmt.Fprintf(w, "\x1b[%d;1H%s\n", i+1, clearLine)
+	}
+	r.prev = append([]string(nil), lines...)
+}
+
+// Reset clears the stored frame (and any sticky or highlight-history
+// state) so the next Draw repaints every line, e.g. after the terminal
+// has been resized or cleared externally.
+func (r *Renderer) Reset() {
+	r.prev = nil
+	r.sticky = nil
+	r.lastHighlighted = nil
+}
+
+// highlightLine wraps the visible runes of line that differ from
+// prevLine in reverse video. Whether a rune differs is decided on both
+// lines with ANSI SGR sequences stripped, so color codes don't
+// spuriously trigger highlights, but line's original SGR sequences are
+// kept in the output so the command's own colors survive highlighting.
+// It reports whether any rune was highlighted.
+func (r *Renderer) highlightLine(i int, prevLine, line string) (string, bool) {
+	prevRunes := []rune(stripANSI(prevLine))
+	curRunes := []rune(stripANSI(line))
+
+	changed := make([]bool, len(curRunes))
+	for j := range curRunes {
+		changed[j] = j >= len(prevRunes) || prevRunes[j] != curRunes[j]
+	}
+
+	if r.mode == HighlightPermanent {
+		for len(r.sticky) <= i {
+			r.sticky = append(r.sticky, nil)
+		}
+		for len(r.sticky[i]) < len(changed) {
+			r.sticky[i] = append(r.sticky[i], false)
+		}
+		for j, c := range changed {
+			if c {
+				r.sticky[i][j] = true
+			}
+			changed[j] = r.sticky[i][j]
+		}
+	}
+
+	var b strings.Builder
+	var any bool
+	visible := 0
+	for _, tok := range scanANSI(line) {
+		if tok.escape {
+			b.WriteString(tok.text)
+			continue
+		}
+		if visible < len(changed) && changed[visible] {
+			any = true
+			b.WriteString(reverseOn)
+			b.WriteString(tok.text)
+			b.WriteString(reverseOff)
+		} else {
+			b.WriteString(tok.text)
+		}
+		visible++
+	}
+	return b.String(), any
+}
+
+// ansiToken is one piece of a line as seen by scanANSI: either a single
+// rune of visible text, or a verbatim ANSI CSI escape sequence (SGR
+// color/style codes, cursor moves, clear-line/screen, and so on).
+type ansiToken struct {
+	escape bool
+	text   string
+}
+
+// scanANSI splits s into a sequence of ansiTokens, keeping each ANSI CSI
+// escape sequence whole (as one atomic token) and everything else as
+// individual runes. It's the shared tokenizer behind both stripANSI
+// (comparison) and highlightLine (display), so the two stay in sync on
+// what counts as an escape sequence.
+//
+// Each sequence is bounded on its own proper CSI final byte rather than
+// the next literal 'm' anywhere in the line, so an unrelated sequence
+// (cursor move, clear-line/screen, ...) can't swallow real visible text
+// that happens to follow it. Keeping a whole sequence as one token
+// rather than splitting it rune by rune also keeps it from being torn
+// apart by a reverse-video wrapper if part of it is later judged changed.
+func scanANSI(s string) []ansiToken {
+	runes := []rune(s)
+	var tokens []ansiToken
+	for i := 0; i < len(runes); i++ {
+		if runes[i] == 0x1b && i+1 < len(runes) && runes[i+1] == '[' {
+			j := i + 2
+			for j < len(runes) && !isCSIFinalByte(runes[j]) {
+				j++
+			}
+			end := j
+			if end < len(runes) {
+				end++
+			}
+			tokens = append(tokens, ansiToken{escape: true, text: string(runes[i:end])})
+			i = end - 1
+			continue
+		}
+		tokens = append(tokens, ansiToken{text: string(runes[i])})
+	}
+	return tokens
+}
+
+// isCSIFinalByte reports whether r is a valid final byte for a CSI
+// escape sequence (ECMA-48), terminating its parameter/intermediate bytes.
+func isCSIFinalByte(r rune) bool {
+	return r >= 0x40 && r <= 0x7e
+}
+
+// stripANSI removes ANSI CSI escape sequences from s so comparisons
+// aren't tripped up by codes that didn't change the visible text.
+func stripANSI(s string) string {
+	var b strings.Builder
+	for _, tok := range scanANSI(s) {
+		if !tok.escape {
+			b.WriteString(tok.text)
+		}
+	}
+	return b.String()
+}
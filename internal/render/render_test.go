@@ -0,0 +1,193 @@
+package render
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func TestDrawRepaintsChangedLinesOnly(t *testing.T) {
+	r := New(HighlightNone, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"a", "b", "c"})
+	if buf.Len() == 0 {
+		t.Fatal("expected first draw to write the whole frame")
+	}
+
+	buf.Reset()
+	r.Draw(&buf, []string{"a", "B", "c"})
+
+	out := buf.String()
+	if strings.Contains(out, "\x1b[1;1H") {
+		t.Errorf("unchanged line 1 should not be repainted, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[2;1H") {
+		t.Errorf("changed line 2 should be repainted, got %q", out)
+	}
+	if strings.Contains(out, "\x1b[3;1H") {
+		t.Errorf("unchanged line 3 should not be repainted, got %q", out)
+	}
+}
+
+func TestDrawClearsShorterFrame(t *testing.T) {
+	r := New(HighlightNone, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"a", "b", "c"})
+	buf.Reset()
+	r.Draw(&buf, []string{"a"})
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[2;1H"+clearLine) {
+		t.Errorf("expected leftover line 2 to be cleared, got %q", out)
+	}
+	if !strings.Contains(out, "\x1b[3;1H"+clearLine) {
+		t.Errorf("expected leftover line 3 to be cleared, got %q", out)
+	}
+}
+
+func TestResetRepaintsEverything(t *testing.T) {
+	r := New(HighlightNone, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"a", "b"})
+	r.Reset()
+
+	buf.Reset()
+	r.Draw(&buf, []string{"a", "b"})
+	if buf.Len() == 0 {
+		t.Fatal("expected draw after Reset to repaint every line")
+	}
+}
+
+func TestHighlightOnMarksOnlyChangedRunes(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"abc"})
+	buf.Reset()
+	r.Draw(&buf, []string{"aXc"})
+
+	out := buf.String()
+	if !strings.Contains(out, reverseOn+"X"+reverseOff) {
+		t.Errorf("expected changed rune to be wrapped in reverse video, got %q", out)
+	}
+	if strings.Contains(out, reverseOn+"a"+reverseOff) || strings.Contains(out, reverseOn+"c"+reverseOff) {
+		t.Errorf("unchanged runes should not be highlighted, got %q", out)
+	}
+}
+
+func TestHighlightIgnoresANSIWhenComparing(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"\x1b[31mabc\x1b[0m"})
+	buf.Reset()
+	r.Draw(&buf, []string{"\x1b[32mabc\x1b[0m"})
+
+	out := buf.String()
+	if strings.Contains(out, reverseOn) {
+		t.Errorf("a color-only change should not be highlighted, got %q", out)
+	}
+}
+
+func TestHighlightDoesNotSwallowNonSGREscapes(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	// "\x1b[K" is a clear-line CSI sequence, not SGR; it must not eat
+	// the "Xc" that follows it up to the next 'm'.
+	r.Draw(&buf, []string{"\x1b[Kabc"})
+	buf.Reset()
+	r.Draw(&buf, []string{"\x1b[KaXc"})
+
+	out := buf.String()
+	if !strings.Contains(out, reverseOn+"X"+reverseOff) {
+		t.Errorf("expected the changed rune after a non-SGR escape to be highlighted, got %q", out)
+	}
+}
+
+func TestHighlightKeepsNonSGREscapesIntact(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"\x1b[5;1Habc"})
+	buf.Reset()
+	r.Draw(&buf, []string{"\x1b[5;2Habc"}) // the cursor-move sequence itself changed, not the text
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[5;2H") {
+		t.Errorf("expected the cursor-move sequence to survive intact rather than be split apart, got %q", out)
+	}
+}
+
+func TestHighlightKeepsOriginalColors(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"\x1b[31mabc\x1b[0m"})
+	buf.Reset()
+	r.Draw(&buf, []string{"\x1b[31maXc\x1b[0m"})
+
+	out := buf.String()
+	if !strings.Contains(out, "\x1b[31m") || !strings.Contains(out, "\x1b[0m") {
+		t.Errorf("expected the line's own color codes to survive highlighting, got %q", out)
+	}
+	if !strings.Contains(out, reverseOn+"X"+reverseOff) {
+		t.Errorf("expected changed rune to still be wrapped in reverse video, got %q", out)
+	}
+}
+
+func TestHighlightOnClearsStaleHighlightOnUnchangedFrame(t *testing.T) {
+	r := New(HighlightOn, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"abc"})
+	r.Draw(&buf, []string{"aXc"})
+
+	buf.Reset()
+	r.Draw(&buf, []string{"aXc"}) // same text again, unlike HighlightPermanent this should clear
+
+	out := buf.String()
+	if buf.Len() == 0 {
+		t.Fatal("expected a repaint to clear the stale highlight, got no output")
+	}
+	if strings.Contains(out, reverseOn) {
+		t.Errorf("expected the stale highlight to be cleared, got %q", out)
+	}
+}
+
+func TestHeaderLinesAreNeverHighlighted(t *testing.T) {
+	r := New(HighlightOn, 1)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"12:00:00", "abc"})
+	buf.Reset()
+	r.Draw(&buf, []string{"12:00:01", "aXc"})
+
+	out := buf.String()
+	headerLine := out[:strings.Index(out, "\x1b[2;1H")]
+	if strings.Contains(headerLine, reverseOn) {
+		t.Errorf("expected the header line to be drawn plainly, never highlighted, got %q", headerLine)
+	}
+	if !strings.Contains(out, reverseOn+"X"+reverseOff) {
+		t.Errorf("expected the non-header line to still be highlighted, got %q", out)
+	}
+}
+
+func TestHighlightPermanentStaysHighlighted(t *testing.T) {
+	r := New(HighlightPermanent, 0)
+	var buf bytes.Buffer
+
+	r.Draw(&buf, []string{"abc"})
+	r.Draw(&buf, []string{"aXc"})
+
+	buf.Reset()
+	r.Draw(&buf, []string{"aXc"}) // same text again, no new change
+
+	out := buf.String()
+	if !strings.Contains(out, reverseOn+"X"+reverseOff) {
+		t.Errorf("expected the once-changed rune to stay highlighted, got %q", out)
+	}
+}
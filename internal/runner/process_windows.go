@@ -0,0 +1,18 @@
+//go:build windows
+
+package runner
+
+import "os/exec"
+
+// Windows has no equivalent of a Unix process group signal, so both the
+// terminate and kill steps fall back to killing the shell process
+// itself; grandchildren it spawned aren't guaranteed to exit with it.
+func setupProcessGroup(cmd *exec.Cmd) {}
+
+func terminateProcessGroup(cmd *exec.Cmd) {
+	cmd.Process.Kill()
+}
+
+func killProcessGroup(cmd *exec.Cmd) {
+	terminateProcessGroup(cmd)
+}
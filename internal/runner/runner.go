@@ -0,0 +1,131 @@
+// Package runner executes shell commands and streams their stdout/stderr
+// line by line as it arrives, instead of waiting for the command to
+// finish before any output is available.
+package runner
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+)
+
+// Line is a single line of output captured from a running command.
+type Line struct {
+	Text   string
+	Stderr bool
+}
+
+// Result carries the outcome of a finished run. ExitCode is -1 when the
+// command could not be started or exited for a reason other than a
+// non-zero status.
+type Result struct {
+	ExitCode int
+	Err      error
+}
+
+// Run starts commandToRun via shell with shellArgs appended (e.g.
+// "sh", []string{"-c"}) and streams its combined stdout/stderr line by
+// line on the returned channel. The channel is closed once the command
+// exits, after the final Result has been sent on done.
+//
+// On platforms that support it, commandToRun runs in its own process
+// group so that if ctx is canceled the whole group - not just the shell
+// - is sent a terminate signal and, if it hasn't exited within
+// killGrace, a kill signal. Signaling only the shell would leave
+// children it spawned (e.g. the rest of a pipeline) running and holding
+// stdout/stderr open.
+func Run(ctx context.Context, shell string, shellArgs []string, commandToRun string, killGrace time.Duration) (<-chan Line, <-chan Result) {
+	lines := make(chan Line)
+	done := make(chan Result, 1)
+
+	args := append(append([]string{}, shellArgs...), commandToRun)
+	cmd := exec.Command(shell, args...)
+	setupProcessGroup(cmd)
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		fail(lines, done, err)
+		return lines, done
+	}
+	stderr, err := cmd.StderrPipe()
+	if err != nil {
+		fail(lines, done, err)
+		return lines, done
+	}
+	if err := cmd.Start(); err != nil {
+		fail(lines, done, err)
+		return lines, done
+	}
+
+	exited := make(chan struct{})
+	go terminateOnCancel(ctx, cmd, killGrace, exited)
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go scan(stdout, false, lines, &wg)
+	go scan(stderr, true, lines, &wg)
+
+	go func() {
+		wg.Wait()
+		waitErr := cmd.Wait()
+		close(exited)
+
+		exitCode := 0
+		if waitErr != nil {
+			exitCode = -1
+			if exitErr, ok := waitErr.(*exec.ExitError); ok {
+				exitCode = exitErr.ExitCode()
+			}
+		}
+		done <- Result{ExitCode: exitCode, Err: waitErr}
+		close(lines)
+		close(done)
+	}()
+
+	return lines, done
+}
+
+// terminateOnCancel waits for ctx to be canceled, then asks cmd's
+// process group to terminate. If the group hasn't exited within
+// killGrace, it follows up with a kill. It returns early, without
+// escalating to a kill, if exited is closed first.
+func terminateOnCancel(ctx context.Context, cmd *exec.Cmd, killGrace time.Duration, exited <-chan struct{}) {
+	select {
+	case <-exited:
+		return
+	case <-ctx.Done():
+	}
+
+	terminateProcessGroup(cmd)
+	if killGrace <= 0 {
+		return
+	}
+
+	timer := time.NewTimer(killGrace)
+	defer timer.Stop()
+	select {
+	case <-exited:
+	case <-timer.C:
+		killProcessGroup(cmd)
+	}
+}
+
+// fail reports a startup error on done and immediately closes both channels.
+func fail(lines chan<- Line, done chan<- Result, err error) {
+	done <- Result{ExitCode: -1, Err: err}
+	close(lines)
+	close(done)
+}
+
+// scan reads r line by line, forwarding each as a Line until EOF.
+func scan(r io.Reader, stderr bool, lines chan<- Line, wg *sync.WaitGroup) {
+	defer wg.Done()
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		lines <- Line{Text: scanner.Text(), Stderr: stderr}
+	}
+}
@@ -0,0 +1,115 @@
+package runner
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestRunStreamsLines(t *testing.T) {
+	lines, done := Run(context.Background(), "sh", []string{"-c"}, "printf 'one\\ntwo\\n'", time.Second)
+
+	var got []string
+	for line := range lines {
+		got = append(got, line.Text)
+	}
+	result := <-done
+
+	if result.Err != nil {
+		t.Fatalf("unexpected error: %v", result.Err)
+	}
+	if result.ExitCode != 0 {
+		t.Fatalf("expected exit code 0, got %d", result.ExitCode)
+	}
+	want := []string{"one", "two"}
+	if len(got) != len(want) {
+		t.Fatalf("got %v lines, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Fatalf("line %d: got %q, want %q", i, got[i], want[i])
+		}
+	}
+}
+
+func TestRunCapturesExitCode(t *testing.T) {
+	lines, done := Run(context.Background(), "sh", []string{"-c"}, "exit 3", time.Second)
+
+	for range lines {
+	}
+	result := <-done
+
+	if result.ExitCode != 3 {
+		t.Fatalf("expected exit code 3, got %d", result.ExitCode)
+	}
+	if result.Err == nil {
+		t.Fatal("expected non-nil error for non-zero exit")
+	}
+}
+
+func TestRunMarksStderrLines(t *testing.T) {
+	lines, done := Run(context.Background(), "sh", []string{"-c"}, "echo out; echo err 1>&2", time.Second)
+
+	var sawStderr bool
+	for line := range lines {
+		if line.Stderr {
+			sawStderr = true
+		}
+	}
+	<-done
+
+	if !sawStderr {
+		t.Fatal("expected at least one stderr line")
+	}
+}
+
+func TestRunCancelTerminatesTheWholeProcessGroup(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	// sleep runs as a child of the shell; canceling must reach it too,
+	// not just the shell itself, or it would keep stdout open for the
+	// full 5 seconds regardless of the shell's own trap handling it.
+	lines, done := Run(ctx, "sh", []string{"-c"}, "trap 'exit 0' TERM; sleep 5", 2*time.Second)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	for range lines {
+	}
+	result := <-done
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected the run to end shortly after cancellation, took %s", elapsed)
+	}
+	if result.Err != nil {
+		t.Fatalf("expected the shell's trap to exit cleanly, got error: %v", result.Err)
+	}
+}
+
+func TestRunKillsAfterGraceIfUnresponsive(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	start := time.Now()
+	// This command ignores SIGTERM, so Run must escalate to SIGKILL
+	// after killGrace instead of waiting out the full sleep.
+	lines, done := Run(ctx, "sh", []string{"-c"}, "trap '' TERM; sleep 5", 200*time.Millisecond)
+
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+	}()
+
+	for range lines {
+	}
+	result := <-done
+	elapsed := time.Since(start)
+
+	if elapsed > 2*time.Second {
+		t.Fatalf("expected SIGKILL to cut the run short after the grace period, took %s", elapsed)
+	}
+	if result.Err == nil {
+		t.Fatal("expected an error from a run killed by SIGKILL")
+	}
+}
@@ -0,0 +1,74 @@
+// Package schedule computes when the next run of a watched command
+// should start. It supports a "fixed" mode, where the next run starts a
+// fixed interval after the previous one finishes, and a "precise" mode,
+// where runs are aligned to absolute clock boundaries so long-running
+// commands don't push every later tick further and further behind.
+package schedule
+
+import "time"
+
+// Mode selects how the delay before the next run is computed.
+type Mode string
+
+const (
+	// ModeFixed starts the next run interval after the previous run
+	// finishes, like `watch -n`.
+	ModeFixed Mode = "fixed"
+	// ModePrecise aligns runs to absolute multiples of interval since
+	// the scheduler was created, regardless of how long runs take.
+	ModePrecise Mode = "precise"
+)
+
+// Overlap selects what happens when a run is still in flight once the
+// next tick is due.
+type Overlap string
+
+const (
+	// OverlapSkip lets the in-flight run finish and drops any ticks
+	// that occurred while it was still running.
+	OverlapSkip Overlap = "skip"
+	// OverlapQueue starts the next run immediately once the in-flight
+	// one finishes, instead of waiting for the next aligned boundary.
+	OverlapQueue Overlap = "queue"
+	// OverlapKill cancels the in-flight run once the next tick is due.
+	OverlapKill Overlap = "kill"
+)
+
+// Stats reports the scheduler's view of recent runs, suitable for
+// display in a header line.
+type Stats struct {
+	LastDuration time.Duration
+	SkippedTicks int
+}
+
+// Scheduler decides how long to wait before the next run, given how long
+// the previous run took.
+type Scheduler struct {
+	interval time.Duration
+	mode     Mode
+	anchor   time.Time
+}
+
+// New returns a Scheduler that paces runs at interval using mode,
+// anchored to the current time.
+func New(interval time.Duration, mode Mode) *Scheduler {
+	return &Scheduler{interval: interval, mode: mode, anchor: time.Now()}
+}
+
+// NextDelay returns how long to wait before starting the next run, given
+// that the previous run took runDuration. For ModePrecise it also
+// reports how many interval boundaries were missed because the previous
+// run overran; for ModeFixed it always reports zero.
+func (s *Scheduler) NextDelay(runDuration time.Duration) (delay time.Duration, skipped int) {
+	if s.mode != ModePrecise {
+		return s.interval, 0
+	}
+
+	elapsed := time.Since(s.anchor)
+	boundaries := elapsed / s.interval
+	nextBoundary := s.interval * (boundaries + 1)
+	if runDuration > s.interval {
+		skipped = int(runDuration/s.interval) - 1
+	}
+	return nextBoundary - elapsed, skipped
+}
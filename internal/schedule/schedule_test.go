@@ -0,0 +1,54 @@
+package schedule
+
+import (
+	"testing"
+	"time"
+)
+
+func TestNextDelayFixedWaitsFullIntervalAfterRun(t *testing.T) {
+	s := &Scheduler{interval: 2 * time.Second, mode: ModeFixed}
+
+	delay, skipped := s.NextDelay(500 * time.Millisecond)
+	if delay != 2*time.Second {
+		t.Errorf("got delay %v, want the full 2s interval after the run finishes", delay)
+	}
+	if skipped != 0 {
+		t.Errorf("fixed mode should never report skipped ticks, got %d", skipped)
+	}
+}
+
+func TestNextDelayFixedWaitsFullIntervalAfterLongRun(t *testing.T) {
+	s := &Scheduler{interval: 2 * time.Second, mode: ModeFixed}
+
+	delay, _ := s.NextDelay(3 * time.Second)
+	if delay != 2*time.Second {
+		t.Errorf("expected the full 2s interval even after a run longer than the interval, got %v", delay)
+	}
+}
+
+func TestNextDelayPreciseAlignsToNextBoundary(t *testing.T) {
+	interval := time.Second
+	// anchor 1.5 intervals ago, so the next boundary is ~0.5s out.
+	s := &Scheduler{interval: interval, mode: ModePrecise, anchor: time.Now().Add(-1500 * time.Millisecond)}
+
+	delay, skipped := s.NextDelay(0)
+	if skipped != 0 {
+		t.Errorf("expected no skipped ticks without an overrun, got %d", skipped)
+	}
+	if delay <= 0 || delay > 600*time.Millisecond {
+		t.Errorf("got delay %v, want roughly 0.5s until the next boundary", delay)
+	}
+}
+
+func TestNextDelayPreciseReportsSkippedTicksOnOverrun(t *testing.T) {
+	interval := time.Second
+	s := &Scheduler{interval: interval, mode: ModePrecise, anchor: time.Now().Add(-100 * time.Millisecond)}
+
+	delay, skipped := s.NextDelay(3500 * time.Millisecond)
+	if skipped != 2 {
+		t.Errorf("got skipped %d, want 2 for a run that overran by 2.5 extra intervals", skipped)
+	}
+	if delay < 0 || delay > interval {
+		t.Errorf("got delay %v, want a sub-interval delay until the next boundary", delay)
+	}
+}
@@ -1,50 +1,156 @@
 package main
 
 import (
+    "context"
+    "flag"
     "fmt"
     "os"
-    "os/exec"
     "os/signal"
+    "runtime"
     "strconv"
     "strings"
     "syscall"
     "time"
+
+    "golang.org/x/term"
+
+    "github.com/stewthestew/watchpls/internal/platform"
+    "github.com/stewthestew/watchpls/internal/render"
+    "github.com/stewthestew/watchpls/internal/runner"
+    "github.com/stewthestew/watchpls/internal/schedule"
 )
 
-// clearScreen attempts to clear the terminal screen.
-// It tries 'clear' for Unix-like systems and 'cls' for Windows.
-func clearScreen() {
-    var cmd *exec.Cmd
-    // Determine the correct clear command based on the operating system
-    if os.Getenv("OS") == "Windows_NT" {
-        cmd = exec.Command("cmd", "/c", "cls")
-    } else {
-        cmd = exec.Command("clear")
-    }
-    // We don't care about potential errors here, as clearing might not work
-    // in all terminal environments. We just try our best.
-    cmd.Stdout = os.Stdout
-    cmd.Run()
+// runOutcome carries the result of one run together with how long it took.
+type runOutcome struct {
+    duration time.Duration
+    result   runner.Result
+}
+
+// header tracks the information shown in the title line: the static
+// interval/command and the outcome of the most recently finished run.
+type header struct {
+    interval time.Duration
+    command  string
+    stats    *schedule.Stats
+    lastExit int
+    lastErr  error
+}
+
+// Line renders the current header as a single line of text.
+func (h *header) Line() string {
+    status := "ok"
+    if h.lastErr != nil {
+        if h.lastExit >= 0 {
+            status = fmt.Sprintf("exit %d", h.lastExit)
+        } else {
+            status = "error"
+        }
+    }
+    line := fmt.Sprintf("Every %s: %s    %s    last: %s  status: %s",
+        h.interval, h.command, time.Now().Format("15:04:05"), h.stats.LastDuration.Round(time.Millisecond), status)
+    if h.stats.SkippedTicks > 0 {
+        line += fmt.Sprintf("  skipped: %d", h.stats.SkippedTicks)
+    }
+    return line
+}
+
+// runOnce streams one run of commandToRun and repaints only the output
+// lines (and, in a highlight mode, the changed characters) that differ
+// from the previous run. Unless showTitle is false, h's header line is
+// prepended to every frame.
+func runOnce(ctx context.Context, shell platform.Shell, commandToRun string, rnd *render.Renderer, killGrace time.Duration, showTitle bool, h *header) runner.Result {
+    lines, done := runner.Run(ctx, shell.Name, shell.Args, commandToRun, killGrace)
+
+    var captured []string
+    draw := func() {
+        frame := captured
+        if showTitle {
+            frame = append([]string{h.Line()}, captured...)
+        }
+        rnd.Draw(os.Stdout, frame)
+    }
+
+    for line := range lines {
+        captured = append(captured, line.Text)
+        draw()
+    }
+
+    result := <-done
+    if result.Err != nil {
+        if result.ExitCode >= 0 {
+            captured = append(captured, fmt.Sprintf("--- Command exited with non-zero status: %d ---", result.ExitCode))
+        } else {
+            captured = append(captured, fmt.Sprintf("Error running command: %v", result.Err))
+        }
+        draw()
+    }
+    return result
 }
 
 func main() {
-    // Argument parsing and validation
-    if len(os.Args) < 3 {
-        fmt.Println("Usage: go run main.go <interval_seconds> <command_to_run>")
-        fmt.Println("Example: go run main.go 2 \"ls -l --color=always\"") // Added --color=always for example
-        fmt.Println("         go run main.go 1 \"date\"")
+    mode := flag.String("mode", string(schedule.ModeFixed), "scheduling mode: fixed or precise")
+    overlapFlag := flag.String("overlap", string(schedule.OverlapSkip), "what to do when a run is still active at the next tick: skip, queue, or kill")
+    killGrace := flag.Duration("kill-grace", 5*time.Second, "grace period between SIGTERM and SIGKILL for an overrunning run")
+    usePowerShell := flag.Bool("powershell", false, "invoke the command via 'powershell -Command' instead of 'cmd /c' on Windows")
+    noTitle := flag.Bool("no-title", false, "hide the header line showing the interval, command, last run duration, and exit status")
+    diffFlag := flag.Bool("d", false, "highlight characters that changed since the previous run (shorthand for --differences=on)")
+    differencesFlag := flag.String("differences", "", "highlight characters that changed since the previous run: on or permanent")
+    quitGrace := flag.Duration("quit-grace", 2*time.Second, "how long to let the running command exit on its own after the first Ctrl+C before forcing quit")
+    flag.Usage = func() {
+        fmt.Println("Usage: watchpls [flags] <interval_seconds> <command_to_run>")
+        fmt.Println("Example: watchpls 2 \"ls -l --color=always\"")
+        fmt.Println("         watchpls --mode=precise --overlap=kill 1 \"date\"")
+        flag.PrintDefaults()
+    }
+    flag.Parse()
+
+    args := flag.Args()
+    if len(args) < 2 {
+        flag.Usage()
         os.Exit(1)
     }
 
-    intervalStr := os.Args[1]
-    interval, err := strconv.ParseFloat(intervalStr, 64)
+    interval, err := strconv.ParseFloat(args[0], 64)
     if err != nil || interval <= 0 {
         fmt.Printf("Error: Invalid interval provided. It must be a positive number: %v\n", err)
         os.Exit(1)
     }
+    intervalDuration := time.Duration(interval * float64(time.Second))
 
-    // Join all subsequent arguments to form the complete command string
-    commandToRun := strings.Join(os.Args[2:], " ")
+    schedMode := schedule.Mode(*mode)
+    if schedMode != schedule.ModeFixed && schedMode != schedule.ModePrecise {
+        fmt.Printf("Error: unknown --mode %q, must be fixed or precise\n", *mode)
+        os.Exit(1)
+    }
+    overlap := schedule.Overlap(*overlapFlag)
+    if overlap != schedule.OverlapSkip && overlap != schedule.OverlapQueue && overlap != schedule.OverlapKill {
+        fmt.Printf("Error: unknown --overlap %q, must be skip, queue, or kill\n", *overlapFlag)
+        os.Exit(1)
+    }
+    if overlap == schedule.OverlapKill && schedMode != schedule.ModePrecise {
+        fmt.Println("Error: --overlap=kill requires --mode=precise; fixed mode never has an overlapping tick to kill")
+        os.Exit(1)
+    }
+
+    differences := *differencesFlag
+    if *diffFlag && differences == "" {
+        differences = "on"
+    }
+    var diffMode render.HighlightMode
+    switch differences {
+    case "":
+        diffMode = render.HighlightNone
+    case "on":
+        diffMode = render.HighlightOn
+    case "permanent":
+        diffMode = render.HighlightPermanent
+    default:
+        fmt.Printf("Error: unknown --differences %q, must be on or permanent\n", differences)
+        os.Exit(1)
+    }
+
+    // Join all remaining arguments to form the complete command string
+    commandToRun := strings.Join(args[1:], " ")
 
     time.Sleep(1 * time.Second) // Give the user a moment to read the message
 
@@ -52,50 +158,96 @@ func main() {
     sigChan := make(chan os.Signal, 1)
     signal.Notify(sigChan, syscall.SIGINT, syscall.SIGTERM)
 
-    // Use a ticker for precise, regular intervals
-    ticker := time.NewTicker(time.Duration(interval * float64(time.Second)))
-    defer ticker.Stop() // Ensure the ticker is stopped when main exits
+    plat := platform.For(runtime.GOOS)
+    if *usePowerShell {
+        plat.Shell = platform.PowerShell
+    }
+    plat.ClearScreen(os.Stdout) // start from a clean terminal before the diff renderer takes over
+
+    // Save the terminal state so it can be restored on exit even if a
+    // killed child left it in raw mode.
+    stdinFd := int(os.Stdin.Fd())
+    termState, termErr := term.GetState(stdinFd)
+    exitRestoring := func() {
+        if termErr == nil {
+            term.Restore(stdinFd, termState)
+        }
+        fmt.Println("\nExiting watch alternative.")
+        os.Exit(0)
+    }
+
+    headerLines := 0
+    if !*noTitle {
+        headerLines = 1
+    }
+    rnd := render.New(diffMode, headerLines)
+    sched := schedule.New(intervalDuration, schedMode)
+    stats := schedule.Stats{}
+    h := &header{interval: intervalDuration, command: commandToRun, stats: &stats, lastExit: -1}
 
     // Main loop for refreshing the command output
     for {
+        ctx, cancel := context.WithCancel(context.Background())
+        runDone := make(chan runOutcome, 1)
+        go func() {
+            start := time.Now()
+            result := runOnce(ctx, plat.Shell, commandToRun, rnd, *killGrace, !*noTitle, h)
+            runDone <- runOutcome{duration: time.Since(start), result: result}
+        }()
+
+        var boundary <-chan time.Time
+        var boundaryTimer *time.Timer
+        if schedMode == schedule.ModePrecise && overlap == schedule.OverlapKill {
+            boundaryTimer = time.NewTimer(intervalDuration)
+            boundary = boundaryTimer.C
+        }
+
+        var outcome runOutcome
         select {
         case <-sigChan:
-            // If Ctrl+C is caught, clean up and exit
-            fmt.Println("\nExiting watch alternative.")
-            clearScreen() // Clear the screen one last time for a clean terminal
-            os.Exit(0)
-        case <-ticker.C:
-            // --- NEW FLICKER-FREE SEQUENCE ---
-
-            // 1. Prepare the command to be executed
-            var cmd *exec.Cmd
-            if os.Getenv("OS") == "Windows_NT" {
-                // On Windows, commands are typically run via 'cmd /c'
-                cmd = exec.Command("cmd", "/c", commandToRun)
-            } else {
-                // On Unix-like systems, commands are run via 'sh -c'
-                cmd = exec.Command("sh", "-c", commandToRun)
+            // First Ctrl+C: ask the running command to exit and give it
+            // quitGrace to do so gracefully; a second Ctrl+C (or the
+            // grace period expiring) tears down immediately.
+            fmt.Printf("\npress Ctrl+C again within %s to force quit\n", *quitGrace)
+            cancel()
+            select {
+            case <-sigChan:
+            case <-runDone:
+            case <-time.After(*quitGrace):
             }
+            exitRestoring()
+        case outcome = <-runDone:
+            if boundaryTimer != nil {
+                boundaryTimer.Stop()
+            }
+            cancel()
+        case <-boundary:
+            // The run is still active past the next aligned tick: cancel
+            // it so the schedule can realign (SIGTERM, then SIGKILL after
+            // killGrace if it doesn't exit in time).
+            cancel()
+            outcome = <-runDone
+            stats.SkippedTicks++
+        }
 
-            // 2. Execute the command and wait for it to finish, capturing all output
-            //    This step embodies "execute", "wait", and "done"
-            output, cmdErr := cmd.CombinedOutput()
-
-            // 3. NOW that the new output is fully ready, clear the screen
-            clearScreen()
+        stats.LastDuration = outcome.duration
+        h.lastExit = outcome.result.ExitCode
+        h.lastErr = outcome.result.Err
 
-            // 4. Print the header and the captured output
-            fmt.Print(string(output)) // Print the captured output, including color codes
+        delay, skipped := sched.NextDelay(stats.LastDuration)
+        stats.SkippedTicks += skipped
+        if schedMode == schedule.ModePrecise && overlap == schedule.OverlapQueue && stats.LastDuration > intervalDuration {
+            delay = 0
+        }
 
-            // Handle any errors from the executed command, printing them after the main output
-            if cmdErr != nil {
-                if exitErr, ok := cmdErr.(*exec.ExitError); ok {
-                    fmt.Printf("\n--- Command exited with non-zero status: %d ---\n", exitErr.ExitCode())
-                } else {
-                    fmt.Printf("\nError running command: %v\n", cmdErr)
-                }
+        if delay > 0 {
+            select {
+            case <-sigChan:
+                // No command is running between ticks, so there's
+                // nothing to wait on: exit right away.
+                exitRestoring()
+            case <-time.After(delay):
             }
-            // The `ticker.C` will handle the waiting for the next interval automatically
         }
     }
 }